@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// unifiedDiff returns a unified diff of onDisk against generated, using name
+// as the file label on both sides. An empty result means the two are
+// identical. A missing on-disk file is treated as empty content, so a file
+// this run would newly create shows up as a diff adding every line.
+func unifiedDiff(name string, onDisk, generated []byte) (string, error) {
+	if bytes.Equal(onDisk, generated) {
+		return "", nil
+	}
+	diff := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(onDisk)),
+		B:        difflib.SplitLines(string(generated)),
+		FromFile: name,
+		FromDate: "on disk",
+		ToFile:   name,
+		ToDate:   "generated",
+		Context:  3,
+	}
+	text, err := difflib.GetUnifiedDiffString(diff)
+	if err != nil {
+		return "", fmt.Errorf("could not compute diff for %s: %w", name, err)
+	}
+	return text, nil
+}
+
+// checkGenerated compares the groups.yaml and dashboard configs this run
+// would produce against what's on disk at testGridConfigDir, returning a
+// unified diff of every file that differs. An empty result means the
+// checked-in config already matches what this run would generate, including
+// that no stale dashboard config (one no longer backed by a release config)
+// is left behind on disk.
+func checkGenerated(testGridConfigDir, groupFile string, groupsOnDisk, groupsGenerated []byte, dashboards []dashboard, generated map[string][]byte) (string, error) {
+	var buf bytes.Buffer
+
+	diff, err := unifiedDiff(groupFile, groupsOnDisk, groupsGenerated)
+	if err != nil {
+		return "", err
+	}
+	buf.WriteString(diff)
+
+	for _, dash := range dashboards {
+		dashPath := path.Join(testGridConfigDir, fmt.Sprintf("%s.yaml", dash.Name))
+		onDisk, err := os.ReadFile(dashPath)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("could not read %s: %w", dashPath, err)
+		}
+		diff, err := unifiedDiff(dashPath, onDisk, generated[dash.Name])
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(diff)
+	}
+
+	staleDiff, err := diffManagedDashboards(testGridConfigDir, generated)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range staleDiff.Removed {
+		stalePath := path.Join(testGridConfigDir, fmt.Sprintf("%s.yaml", name))
+		onDisk, err := os.ReadFile(stalePath)
+		if err != nil {
+			return "", fmt.Errorf("could not read %s: %w", stalePath, err)
+		}
+		diff, err := unifiedDiff(stalePath, onDisk, nil)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(diff)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestCheckGeneratedInSync(t *testing.T) {
+	dir := t.TempDir()
+	groupFile := path.Join(dir, "groups.yaml")
+	dashboards := []dashboard{testDashboard("redhat-openshift-ocp-release-4.9-blocking", "ocp", "4.9", "blocking", "/release/ocp-4.9.json")}
+	generated := map[string][]byte{"redhat-openshift-ocp-release-4.9-blocking": []byte("content\n")}
+	if err := os.WriteFile(path.Join(dir, "redhat-openshift-ocp-release-4.9-blocking.yaml"), generated["redhat-openshift-ocp-release-4.9-blocking"], 0600); err != nil {
+		t.Fatalf("could not write dashboard config: %v", err)
+	}
+	groups := []byte("dashboard_groups:\n- name: redhat\n")
+
+	diff, err := checkGenerated(dir, groupFile, groups, groups, dashboards, generated)
+	if err != nil {
+		t.Fatalf("checkGenerated() returned error: %v", err)
+	}
+	if diff != "" {
+		t.Errorf("expected no diff when checked-in config matches, got:\n%s", diff)
+	}
+}
+
+func TestCheckGeneratedDrifted(t *testing.T) {
+	dir := t.TempDir()
+	groupFile := path.Join(dir, "groups.yaml")
+	dashboards := []dashboard{testDashboard("redhat-openshift-ocp-release-4.9-blocking", "ocp", "4.9", "blocking", "/release/ocp-4.9.json")}
+	generated := map[string][]byte{"redhat-openshift-ocp-release-4.9-blocking": []byte("new content\n")}
+	if err := os.WriteFile(path.Join(dir, "redhat-openshift-ocp-release-4.9-blocking.yaml"), []byte("old content\n"), 0600); err != nil {
+		t.Fatalf("could not write dashboard config: %v", err)
+	}
+	groups := []byte("dashboard_groups:\n- name: redhat\n")
+
+	diff, err := checkGenerated(dir, groupFile, groups, groups, dashboards, generated)
+	if err != nil {
+		t.Fatalf("checkGenerated() returned error: %v", err)
+	}
+	if !strings.Contains(diff, "old content") || !strings.Contains(diff, "new content") {
+		t.Errorf("expected diff to mention drifted dashboard content, got:\n%s", diff)
+	}
+}
+
+func TestCheckGeneratedStaleDashboard(t *testing.T) {
+	dir := t.TempDir()
+	groupFile := path.Join(dir, "groups.yaml")
+	// no dashboards generated this run, e.g. the release was removed from --release-config
+	var dashboards []dashboard
+	generated := map[string][]byte{}
+	stalePath := path.Join(dir, "redhat-openshift-ocp-release-4.8-blocking.yaml")
+	if err := os.WriteFile(stalePath, []byte("still here\n"), 0600); err != nil {
+		t.Fatalf("could not write stale dashboard config: %v", err)
+	}
+	groups := []byte("dashboard_groups:\n- name: redhat\n")
+
+	diff, err := checkGenerated(dir, groupFile, groups, groups, dashboards, generated)
+	if err != nil {
+		t.Fatalf("checkGenerated() returned error: %v", err)
+	}
+	if diff == "" {
+		t.Fatal("expected a non-empty diff for a stale dashboard left on disk, got none")
+	}
+	if !strings.Contains(diff, "redhat-openshift-ocp-release-4.8-blocking.yaml") {
+		t.Errorf("expected diff to name the stale dashboard file, got:\n%s", diff)
+	}
+}
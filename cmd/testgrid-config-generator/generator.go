@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"text/template"
+
+	"github.com/GoogleCloudPlatform/testgrid/config"
+	"sigs.k8s.io/yaml"
+)
+
+// templateFields are the values exposed to every template in a generatorProfile.
+// They are re-derived for each job so per-job overrides (like the job name) are
+// available alongside the per-release values.
+type templateFields struct {
+	Product string
+	Version string
+	Role    string
+	Name    string
+}
+
+// generatorProfile holds the values used to render a DashboardTab and TestGroup
+// for a job. GcsPrefixTemplate, FileBugTitleTemplate and FileBugBodyTemplate are
+// rendered as text/template snippets with templateFields; the other fields are
+// used verbatim. Any field left empty falls back to the "default" profile's
+// value for that field, so a product override only needs to set the fields it
+// wants to change.
+type generatorProfile struct {
+	OpenTestTemplateURL   string `json:"openTestTemplateURL,omitempty"`
+	FileBugURL            string `json:"fileBugURL,omitempty"`
+	FileBugTitleTemplate  string `json:"fileBugTitleTemplate,omitempty"`
+	FileBugBodyTemplate   string `json:"fileBugBodyTemplate,omitempty"`
+	OpenBugURL            string `json:"openBugURL,omitempty"`
+	ResultsUrlTemplate    string `json:"resultsUrlTemplate,omitempty"`
+	CodeSearchPath        string `json:"codeSearchPath,omitempty"`
+	CodeSearchUrlTemplate string `json:"codeSearchUrlTemplate,omitempty"`
+	GcsPrefixTemplate     string `json:"gcsPrefixTemplate,omitempty"`
+}
+
+// generatorConfig is the on-disk shape of the --generator-config file. The
+// "default" profile is applied to every product; entries under "products" are
+// keyed by the lowercase product name (e.g. "okd", "ocp") and override only the
+// fields they set.
+type generatorConfig struct {
+	Default  generatorProfile            `json:"default"`
+	Products map[string]generatorProfile `json:"products,omitempty"`
+}
+
+// defaultGeneratorConfig reproduces the historical, hardcoded openshift/origin
+// behavior so that omitting --generator-config keeps existing output unchanged.
+func defaultGeneratorConfig() generatorConfig {
+	return generatorConfig{
+		Default: generatorProfile{
+			OpenTestTemplateURL:   "https://prow.svc.ci.openshift.org/view/gcs/<gcs_prefix>/<changelist>",
+			FileBugURL:            "https://github.com/openshift/origin/issues/new",
+			FileBugTitleTemplate:  "E2E: <test-name>",
+			FileBugBodyTemplate:   "<test-url>",
+			OpenBugURL:            "https://github.com/openshift/origin/issues/",
+			ResultsUrlTemplate:    "https://prow.svc.ci.openshift.org/job-history/<gcs_prefix>",
+			CodeSearchPath:        "https://github.com/openshift/origin/search",
+			CodeSearchUrlTemplate: "https://github.com/openshift/origin/compare/<start-custom-0>...<end-custom-0>",
+			GcsPrefixTemplate:     "origin-ci-test/logs/{{.Name}}",
+		},
+	}
+}
+
+// loadGeneratorConfig reads a generator profile from disk. An empty path
+// returns defaultGeneratorConfig so the tool keeps working without the flag.
+func loadGeneratorConfig(path string) (generatorConfig, error) {
+	if path == "" {
+		return defaultGeneratorConfig(), nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return generatorConfig{}, fmt.Errorf("could not read generator config at %s: %w", path, err)
+	}
+	cfg := defaultGeneratorConfig()
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return generatorConfig{}, fmt.Errorf("could not unmarshal generator config at %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// mergedProfile overlays a product-specific profile on top of the default one,
+// field by field, so product overrides only need to specify what differs.
+func mergedProfile(cfg generatorConfig, product string) generatorProfile {
+	merged := cfg.Default
+	override, ok := cfg.Products[product]
+	if !ok {
+		return merged
+	}
+	if override.OpenTestTemplateURL != "" {
+		merged.OpenTestTemplateURL = override.OpenTestTemplateURL
+	}
+	if override.FileBugURL != "" {
+		merged.FileBugURL = override.FileBugURL
+	}
+	if override.FileBugTitleTemplate != "" {
+		merged.FileBugTitleTemplate = override.FileBugTitleTemplate
+	}
+	if override.FileBugBodyTemplate != "" {
+		merged.FileBugBodyTemplate = override.FileBugBodyTemplate
+	}
+	if override.OpenBugURL != "" {
+		merged.OpenBugURL = override.OpenBugURL
+	}
+	if override.ResultsUrlTemplate != "" {
+		merged.ResultsUrlTemplate = override.ResultsUrlTemplate
+	}
+	if override.CodeSearchPath != "" {
+		merged.CodeSearchPath = override.CodeSearchPath
+	}
+	if override.CodeSearchUrlTemplate != "" {
+		merged.CodeSearchUrlTemplate = override.CodeSearchUrlTemplate
+	}
+	if override.GcsPrefixTemplate != "" {
+		merged.GcsPrefixTemplate = override.GcsPrefixTemplate
+	}
+	return merged
+}
+
+// generator renders DashboardTabs and TestGroups from parsed generator
+// profiles, one profile per product plus a fallback default.
+type generator struct {
+	cfg       generatorConfig
+	templates map[string]*template.Template
+}
+
+// newGenerator parses every template referenced by the config up front so that
+// a malformed template is reported at startup rather than mid-run.
+func newGenerator(cfg generatorConfig) (*generator, error) {
+	g := &generator{cfg: cfg, templates: map[string]*template.Template{}}
+	products := []string{""}
+	for product := range cfg.Products {
+		products = append(products, product)
+	}
+	for _, product := range products {
+		profile := mergedProfile(cfg, product)
+		for _, field := range []struct {
+			name, value string
+		}{
+			{"gcsPrefix", profile.GcsPrefixTemplate},
+			{"fileBugTitle", profile.FileBugTitleTemplate},
+			{"fileBugBody", profile.FileBugBodyTemplate},
+		} {
+			key := product + "/" + field.name
+			tmpl, err := template.New(key).Parse(field.value)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse %s template for product %q: %w", field.name, product, err)
+			}
+			g.templates[key] = tmpl
+		}
+	}
+	return g, nil
+}
+
+func (g *generator) render(product, key string, fields templateFields) (string, error) {
+	tmpl, ok := g.templates[product+"/"+key]
+	if !ok {
+		tmpl, ok = g.templates["/"+key]
+		if !ok {
+			return "", fmt.Errorf("no %s template registered for product %q", key, product)
+		}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, fields); err != nil {
+		return "", fmt.Errorf("could not render %s template for product %q: %w", key, product, err)
+	}
+	return buf.String(), nil
+}
+
+// dashboardTabFor builds a dashboard tab, using the generator profile for the
+// given product (falling back to the default profile) to fill in URLs that
+// used to be hardcoded to openshift/origin.
+func (g *generator) dashboardTabFor(product, version, role, name string) (*config.DashboardTab, error) {
+	profile := mergedProfile(g.cfg, product)
+	fields := templateFields{Product: product, Version: version, Role: role, Name: name}
+
+	fileBugTitle, err := g.render(product, "fileBugTitle", fields)
+	if err != nil {
+		return nil, err
+	}
+	fileBugBody, err := g.render(product, "fileBugBody", fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config.DashboardTab{
+		Name:             name,
+		TestGroupName:    name,
+		BaseOptions:      "width=10",
+		OpenTestTemplate: &config.LinkTemplate{Url: profile.OpenTestTemplateURL},
+		FileBugTemplate: &config.LinkTemplate{
+			Url: profile.FileBugURL,
+			Options: []*config.LinkOptionsTemplate{
+				{Key: "title", Value: fileBugTitle},
+				{Key: "body", Value: fileBugBody},
+			},
+		},
+		OpenBugTemplate:       &config.LinkTemplate{Url: profile.OpenBugURL},
+		ResultsUrlTemplate:    &config.LinkTemplate{Url: profile.ResultsUrlTemplate},
+		CodeSearchPath:        profile.CodeSearchPath,
+		CodeSearchUrlTemplate: &config.LinkTemplate{Url: profile.CodeSearchUrlTemplate},
+	}, nil
+}
+
+// testGroupFor builds a test group, rendering the GCS prefix from the
+// generator profile for the given product.
+func (g *generator) testGroupFor(product, version, role, name string) (*config.TestGroup, error) {
+	fields := templateFields{Product: product, Version: version, Role: role, Name: name}
+	gcsPrefix, err := g.render(product, "gcsPrefix", fields)
+	if err != nil {
+		return nil, err
+	}
+	return &config.TestGroup{
+		Name:      name,
+		GcsPrefix: gcsPrefix,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergedProfileFallsBackPerField(t *testing.T) {
+	cfg := defaultGeneratorConfig()
+	cfg.Products = map[string]generatorProfile{
+		"ocp": {FileBugURL: "https://issues.example.com/ocp"},
+	}
+
+	merged := mergedProfile(cfg, "ocp")
+	if merged.FileBugURL != "https://issues.example.com/ocp" {
+		t.Errorf("expected FileBugURL override to apply, got %q", merged.FileBugURL)
+	}
+	if merged.OpenBugURL != cfg.Default.OpenBugURL {
+		t.Errorf("expected OpenBugURL to fall back to the default, got %q", merged.OpenBugURL)
+	}
+
+	unknown := mergedProfile(cfg, "okd")
+	if unknown != cfg.Default {
+		t.Errorf("expected a product with no override to get the default profile verbatim, got %+v", unknown)
+	}
+}
+
+func TestNewGeneratorRejectsMalformedTemplate(t *testing.T) {
+	cfg := defaultGeneratorConfig()
+	cfg.Default.GcsPrefixTemplate = "{{.Name"
+
+	if _, err := newGenerator(cfg); err == nil {
+		t.Fatal("expected newGenerator to reject a malformed template, got nil error")
+	}
+}
+
+func TestRenderFallsBackToDefaultTemplate(t *testing.T) {
+	cfg := defaultGeneratorConfig()
+	cfg.Products = map[string]generatorProfile{
+		"ocp": {}, // registered product with no per-field overrides
+	}
+	gen, err := newGenerator(cfg)
+	if err != nil {
+		t.Fatalf("newGenerator() returned error: %v", err)
+	}
+
+	got, err := gen.render("ocp", "gcsPrefix", templateFields{Name: "e2e-aws"})
+	if err != nil {
+		t.Fatalf("render() returned error: %v", err)
+	}
+	if !strings.Contains(got, "e2e-aws") {
+		t.Errorf("expected rendered gcsPrefix to use the default template, got %q", got)
+	}
+
+	if _, err := gen.render("unregistered-product", "gcsPrefix", templateFields{Name: "e2e-aws"}); err != nil {
+		t.Errorf("expected an unregistered product to fall back to the default template, got error: %v", err)
+	}
+
+	if _, err := gen.render("ocp", "no-such-key", templateFields{}); err == nil {
+		t.Error("expected render to error for a key with no registered template")
+	}
+}
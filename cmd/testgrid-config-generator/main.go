@@ -10,6 +10,7 @@ import (
 	"path"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/GoogleCloudPlatform/testgrid/config"
 	"github.com/sirupsen/logrus"
@@ -21,6 +22,11 @@ import (
 type options struct {
 	releaseConfigDir  string
 	testGridConfigDir string
+	generatorConfig   string
+	prune             bool
+	dryRun            bool
+	check             bool
+	manifestPath      string
 }
 
 func (o *options) Validate() error {
@@ -38,6 +44,12 @@ func gatherOptions() options {
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	fs.StringVar(&o.releaseConfigDir, "release-config", "", "Path to Release Controller configuration directory.")
 	fs.StringVar(&o.testGridConfigDir, "testgrid-config", "", "Path to TestGrid configuration directory.")
+	fs.StringVar(&o.generatorConfig, "generator-config", "", "Path to the generator profile config (YAML/JSON). Defaults to the historical openshift/origin profile.")
+	fs.BoolVar(&o.prune, "prune", false, "Remove dashboard configs for release configs that no longer exist, treating --release-config as the source of truth.")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Print the diff --prune would make (added/removed/modified dashboards) without writing anything.")
+	fs.BoolVar(&o.check, "check", false, "Generate in memory and compare against the on-disk TestGrid config, exiting non-zero with a unified diff if they differ. Writes nothing.")
+	fs.BoolVar(&o.check, "verify", false, "Alias for --check.")
+	fs.StringVar(&o.manifestPath, "manifest", "", "Path to write a JSON manifest of generated dashboards (path, digest, source release config, job list) to. Skipped if unset.")
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		logrus.WithError(err).Fatal("could not parse input")
 	}
@@ -48,49 +60,39 @@ func gatherOptions() options {
 type dashboard struct {
 	*config.Dashboard
 	testGroups []*config.TestGroup
+
+	product, version, role string
+	releaseConfigPath      string
+	gen                    *generator
 }
 
-func dashboardFor(product, version, role string) dashboard {
+func dashboardFor(gen *generator, product, version, role, releaseConfigPath string) dashboard {
 	return dashboard{
 		Dashboard: &config.Dashboard{
 			Name:         fmt.Sprintf("redhat-openshift-%s-release-%s-%s", product, version, role),
 			DashboardTab: []*config.DashboardTab{},
 		},
-		testGroups: []*config.TestGroup{},
+		testGroups:        []*config.TestGroup{},
+		product:           product,
+		version:           version,
+		role:              role,
+		releaseConfigPath: releaseConfigPath,
+		gen:               gen,
 	}
 }
 
-// dashboardTabFor builds a dashboard tab with default values injected
-func dashboardTabFor(name string) *config.DashboardTab {
-	return &config.DashboardTab{
-		Name:             name,
-		TestGroupName:    name,
-		BaseOptions:      "width=10",
-		OpenTestTemplate: &config.LinkTemplate{Url: "https://prow.svc.ci.openshift.org/view/gcs/<gcs_prefix>/<changelist>"},
-		FileBugTemplate: &config.LinkTemplate{
-			Url: "https://github.com/openshift/origin/issues/new",
-			Options: []*config.LinkOptionsTemplate{
-				{Key: "title", Value: "E2E: <test-name>"},
-				{Key: "body", Value: "<test-url>"},
-			},
-		},
-		OpenBugTemplate:       &config.LinkTemplate{Url: "https://github.com/openshift/origin/issues/"},
-		ResultsUrlTemplate:    &config.LinkTemplate{Url: "https://prow.svc.ci.openshift.org/job-history/<gcs_prefix>"},
-		CodeSearchPath:        "https://github.com/openshift/origin/search",
-		CodeSearchUrlTemplate: &config.LinkTemplate{Url: "https://github.com/openshift/origin/compare/<start-custom-0>...<end-custom-0>"},
+func (d *dashboard) add(name string) error {
+	tab, err := d.gen.dashboardTabFor(d.product, d.version, d.role, name)
+	if err != nil {
+		return fmt.Errorf("could not render dashboard tab for %s: %w", name, err)
 	}
-}
-
-func testGroupFor(name string) *config.TestGroup {
-	return &config.TestGroup{
-		Name:      name,
-		GcsPrefix: fmt.Sprintf("origin-ci-test/logs/%s", name),
+	testGroup, err := d.gen.testGroupFor(d.product, d.version, d.role, name)
+	if err != nil {
+		return fmt.Errorf("could not render test group for %s: %w", name, err)
 	}
-}
-
-func (d *dashboard) add(name string) {
-	d.Dashboard.DashboardTab = append(d.Dashboard.DashboardTab, dashboardTabFor(name))
-	d.testGroups = append(d.testGroups, testGroupFor(name))
+	d.Dashboard.DashboardTab = append(d.Dashboard.DashboardTab, tab)
+	d.testGroups = append(d.testGroups, testGroup)
+	return nil
 }
 
 // release is a subset of fields from the release controller's config
@@ -128,6 +130,15 @@ func main() {
 		logrus.Fatalf("Invalid options: %v", err)
 	}
 
+	generatorCfg, err := loadGeneratorConfig(o.generatorConfig)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not load generator config")
+	}
+	gen, err := newGenerator(generatorCfg)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not compile generator config")
+	}
+
 	var dashboards []dashboard
 	if err := filepath.Walk(o.releaseConfigDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -158,17 +169,21 @@ func main() {
 			return nil
 		}
 
-		blocking := dashboardFor(product, version, "blocking")
-		informing := dashboardFor(product, version, "informing")
+		blocking := dashboardFor(gen, product, version, "blocking", path)
+		informing := dashboardFor(gen, product, version, "informing", path)
 		for _, job := range releaseConfig.Verify {
 			if job.ProwJob.Name == "release-openshift-origin-installer-e2e-aws-upgrade" {
 				// this job is not sharded by version ... why? who knows
 				continue
 			}
 			if job.Optional {
-				informing.add(job.ProwJob.Name)
+				if err := informing.add(job.ProwJob.Name); err != nil {
+					return err
+				}
 			} else {
-				blocking.add(job.ProwJob.Name)
+				if err := blocking.add(job.ProwJob.Name); err != nil {
+					return err
+				}
 			}
 		}
 		if len(blocking.testGroups) > 0 {
@@ -188,57 +203,114 @@ func main() {
 		dashboardNames.Insert(dash.Name)
 	}
 
+	// render every dashboard config up front so --dry-run and --check can diff
+	// it against disk without writing anything, and --prune knows the
+	// expected set.
+	generated := map[string][]byte{}
+	for _, dash := range dashboards {
+		partialConfig := config.Configuration{
+			TestGroups: dash.testGroups,
+			Dashboards: []*config.Dashboard{dash.Dashboard},
+		}
+		sort.Slice(partialConfig.TestGroups, func(i, j int) bool {
+			return partialConfig.TestGroups[i].Name < partialConfig.TestGroups[j].Name
+		})
+		sort.Slice(partialConfig.Dashboards, func(i, j int) bool {
+			return partialConfig.Dashboards[i].Name < partialConfig.Dashboards[j].Name
+		})
+		for k := range partialConfig.Dashboards {
+			sort.Slice(partialConfig.Dashboards[k].DashboardTab, func(i, j int) bool {
+				return partialConfig.Dashboards[k].DashboardTab[i].Name < partialConfig.Dashboards[k].DashboardTab[j].Name
+			})
+		}
+		data, err := yaml.Marshal(&partialConfig)
+		if err != nil {
+			logrus.WithError(err).Fatalf("Could not marshal TestGrid config for %s", dash.Name)
+		}
+		generated[dash.Name] = data
+	}
+
+	if o.dryRun {
+		diff, err := diffManagedDashboards(o.testGridConfigDir, generated)
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not compute dashboard diff")
+		}
+		if diff.empty() {
+			logrus.Info("No changes to TestGrid dashboards.")
+			return
+		}
+		fmt.Print(diff.String())
+		return
+	}
+
 	groupFile := path.Join(o.testGridConfigDir, "groups.yaml")
-	data, err := ioutil.ReadFile(groupFile)
+	groupsOnDisk, err := ioutil.ReadFile(groupFile)
 	if err != nil {
 		logrus.WithError(err).Fatal("Could not read TestGrid group config")
 	}
 
 	var groups config.Configuration
-	if err := yaml.Unmarshal(data, &groups); err != nil {
+	if err := yaml.Unmarshal(groupsOnDisk, &groups); err != nil {
 		logrus.WithError(err).Fatal("Could not unmarshal TestGrid group config")
 	}
 
 	for _, dashGroup := range groups.DashboardGroups {
 		if dashGroup.Name == "redhat" {
-			dashboardNames.Insert(dashGroup.DashboardNames...)
+			if o.prune {
+				for _, name := range dashGroup.DashboardNames {
+					if !strings.HasPrefix(name, dashboardFilePrefix) || dashboardNames.Has(name) {
+						dashboardNames.Insert(name)
+					}
+				}
+			} else {
+				dashboardNames.Insert(dashGroup.DashboardNames...)
+			}
 			dashGroup.DashboardNames = dashboardNames.List() // sorted implicitly
 		}
 	}
 
-	data, err = yaml.Marshal(&groups)
+	groupsGenerated, err := yaml.Marshal(&groups)
 	if err != nil {
 		logrus.WithError(err).Fatal("Could not marshal TestGrid group config")
 	}
 
-	if err := ioutil.WriteFile(groupFile, data, 0664); err != nil {
+	if o.check {
+		diff, err := checkGenerated(o.testGridConfigDir, groupFile, groupsOnDisk, groupsGenerated, dashboards, generated)
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not compute TestGrid config diff")
+		}
+		if diff == "" {
+			logrus.Info("Checked-in TestGrid config matches what this run would generate.")
+			return
+		}
+		fmt.Print(diff)
+		logrus.Fatal("Checked-in TestGrid config is out of date; run without --check to regenerate it, adding --prune if stale dashboard configs are listed.")
+	}
+
+	if o.prune {
+		removed, err := pruneStaleDashboards(o.testGridConfigDir, dashboardNames)
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not prune stale dashboard configs")
+		}
+		for _, name := range removed {
+			logrus.Infof("Pruned stale dashboard config %s.yaml", name)
+		}
+	}
+
+	if err := ioutil.WriteFile(groupFile, groupsGenerated, 0664); err != nil {
 		logrus.WithError(err).Fatal("Could not write TestGrid group config")
 	}
 
 	// then, rewrite any dashboard configs we are generating
 	for _, dash := range dashboards {
-		partialConfig := config.Configuration{
-			TestGroups: dash.testGroups,
-			Dashboards: []*config.Dashboard{dash.Dashboard},
-		}
-		sort.Slice(partialConfig.TestGroups, func(i, j int) bool {
-			return partialConfig.TestGroups[i].Name < partialConfig.TestGroups[j].Name
-		})
-		sort.Slice(partialConfig.Dashboards, func(i, j int) bool {
-			return partialConfig.Dashboards[i].Name < partialConfig.Dashboards[j].Name
-		})
-		for k := range partialConfig.Dashboards {
-			sort.Slice(partialConfig.Dashboards[k].DashboardTab, func(i, j int) bool {
-				return partialConfig.Dashboards[k].DashboardTab[i].Name < partialConfig.Dashboards[k].DashboardTab[j].Name
-			})
-		}
-		data, err = yaml.Marshal(&partialConfig)
-		if err != nil {
-			logrus.WithError(err).Fatalf("Could not marshal TestGrid config for %s", dash.Name)
+		if err := ioutil.WriteFile(path.Join(o.testGridConfigDir, fmt.Sprintf("%s.yaml", dash.Name)), generated[dash.Name], 0664); err != nil {
+			logrus.WithError(err).Fatalf("Could not write TestGrid config for %s", dash.Name)
 		}
+	}
 
-		if err := ioutil.WriteFile(path.Join(o.testGridConfigDir, fmt.Sprintf("%s.yaml", dash.Name)), data, 0664); err != nil {
-			logrus.WithError(err).Fatalf("Could not write TestGrid config for %s", dash.Name)
+	if o.manifestPath != "" {
+		if err := writeManifest(o.manifestPath, o.testGridConfigDir, dashboards, generated); err != nil {
+			logrus.WithError(err).Fatal("Could not write manifest")
 		}
 	}
 	logrus.Info("Finished generating TestGrid dashboards.")
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+)
+
+// manifestEntry records everything a downstream consumer (automation, or a
+// reviewer on a PR bumping the release configs) needs to know about one
+// generated dashboard without reading its YAML.
+type manifestEntry struct {
+	Path              string   `json:"path"`
+	SHA256            string   `json:"sha256"`
+	ReleaseConfigPath string   `json:"releaseConfigPath"`
+	Product           string   `json:"product"`
+	Version           string   `json:"version"`
+	Role              string   `json:"role"`
+	ProwJobs          []string `json:"prowJobs"`
+}
+
+// manifest is the --manifest output: one entry per dashboard this run
+// generated, sorted by path so it diffs cleanly across runs.
+type manifest struct {
+	Dashboards []manifestEntry `json:"dashboards"`
+}
+
+// buildManifest summarizes the generated dashboards, pairing each with the
+// SHA-256 digest of its rendered YAML and the release config and job list it
+// came from.
+func buildManifest(testGridConfigDir string, dashboards []dashboard, generated map[string][]byte) manifest {
+	var m manifest
+	for _, dash := range dashboards {
+		jobs := make([]string, 0, len(dash.Dashboard.DashboardTab))
+		for _, tab := range dash.Dashboard.DashboardTab {
+			jobs = append(jobs, tab.Name)
+		}
+		sort.Strings(jobs)
+		digest := sha256.Sum256(generated[dash.Name])
+		m.Dashboards = append(m.Dashboards, manifestEntry{
+			Path:              path.Join(testGridConfigDir, fmt.Sprintf("%s.yaml", dash.Name)),
+			SHA256:            hex.EncodeToString(digest[:]),
+			ReleaseConfigPath: dash.releaseConfigPath,
+			Product:           dash.product,
+			Version:           dash.version,
+			Role:              dash.role,
+			ProwJobs:          jobs,
+		})
+	}
+	sort.Slice(m.Dashboards, func(i, j int) bool {
+		return m.Dashboards[i].Path < m.Dashboards[j].Path
+	})
+	return m
+}
+
+// writeManifest renders the manifest as indented JSON and writes it to manifestPath.
+func writeManifest(manifestPath, testGridConfigDir string, dashboards []dashboard, generated map[string][]byte) error {
+	m := buildManifest(testGridConfigDir, dashboards, generated)
+	data, err := json.MarshalIndent(&m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal manifest: %w", err)
+	}
+	if err := ioutil.WriteFile(manifestPath, append(data, '\n'), 0664); err != nil {
+		return fmt.Errorf("could not write manifest to %s: %w", manifestPath, err)
+	}
+	return nil
+}
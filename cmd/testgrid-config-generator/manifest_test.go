@@ -0,0 +1,71 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/testgrid/config"
+)
+
+func testDashboard(name, product, version, role, releaseConfigPath string, tabNames ...string) dashboard {
+	d := dashboardFor(nil, product, version, role, releaseConfigPath)
+	d.Dashboard.Name = name
+	for _, tab := range tabNames {
+		d.Dashboard.DashboardTab = append(d.Dashboard.DashboardTab, &config.DashboardTab{Name: tab})
+	}
+	return d
+}
+
+func TestBuildManifest(t *testing.T) {
+	testGridConfigDir := "/testgrid-config"
+	dashboards := []dashboard{
+		testDashboard("redhat-openshift-ocp-release-4.9-blocking", "ocp", "4.9", "blocking", "/release/ocp-4.9.json", "e2e-b", "e2e-a"),
+	}
+	generated := map[string][]byte{
+		"redhat-openshift-ocp-release-4.9-blocking": []byte("dashboards:\n- name: redhat-openshift-ocp-release-4.9-blocking\n"),
+	}
+
+	m := buildManifest(testGridConfigDir, dashboards, generated)
+
+	if len(m.Dashboards) != 1 {
+		t.Fatalf("expected 1 manifest entry, got %d", len(m.Dashboards))
+	}
+	entry := m.Dashboards[0]
+	if entry.Path != path.Join(testGridConfigDir, "redhat-openshift-ocp-release-4.9-blocking.yaml") {
+		t.Errorf("unexpected path: %s", entry.Path)
+	}
+	if entry.ReleaseConfigPath != "/release/ocp-4.9.json" || entry.Product != "ocp" || entry.Version != "4.9" || entry.Role != "blocking" {
+		t.Errorf("unexpected entry metadata: %+v", entry)
+	}
+	if want := []string{"e2e-a", "e2e-b"}; len(entry.ProwJobs) != 2 || entry.ProwJobs[0] != want[0] || entry.ProwJobs[1] != want[1] {
+		t.Errorf("expected sorted job list %v, got %v", want, entry.ProwJobs)
+	}
+	wantDigest := sha256.Sum256(generated["redhat-openshift-ocp-release-4.9-blocking"])
+	if entry.SHA256 != hex.EncodeToString(wantDigest[:]) {
+		t.Errorf("expected digest %s, got %s", hex.EncodeToString(wantDigest[:]), entry.SHA256)
+	}
+
+	again := buildManifest(testGridConfigDir, dashboards, generated)
+	if again.Dashboards[0].SHA256 != m.Dashboards[0].SHA256 {
+		t.Error("expected digest to be stable across repeated runs over the same content")
+	}
+}
+
+func TestBuildManifestSortedByPath(t *testing.T) {
+	dashboards := []dashboard{
+		testDashboard("redhat-openshift-ocp-release-4.9-informing", "ocp", "4.9", "informing", "/release/ocp-4.9.json"),
+		testDashboard("redhat-openshift-ocp-release-4.8-blocking", "ocp", "4.8", "blocking", "/release/ocp-4.8.json"),
+	}
+	generated := map[string][]byte{
+		"redhat-openshift-ocp-release-4.9-informing": []byte("a"),
+		"redhat-openshift-ocp-release-4.8-blocking":  []byte("b"),
+	}
+
+	m := buildManifest("/testgrid-config", dashboards, generated)
+
+	if len(m.Dashboards) != 2 || m.Dashboards[0].Path > m.Dashboards[1].Path {
+		t.Fatalf("expected manifest entries sorted by path, got %+v", m.Dashboards)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// dashboardFilePrefix identifies the dashboard YAML files this tool owns and
+// is therefore allowed to prune; anything else under --testgrid-config is
+// left untouched even in --prune mode.
+const dashboardFilePrefix = "redhat-openshift-"
+
+// pruneDiff summarizes what --prune would change, for --dry-run reporting.
+type pruneDiff struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+func (d pruneDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+func (d pruneDiff) String() string {
+	var buf bytes.Buffer
+	for _, name := range d.Added {
+		fmt.Fprintf(&buf, "+ %s\n", name)
+	}
+	for _, name := range d.Removed {
+		fmt.Fprintf(&buf, "- %s\n", name)
+	}
+	for _, name := range d.Modified {
+		fmt.Fprintf(&buf, "~ %s\n", name)
+	}
+	return buf.String()
+}
+
+// existingManagedDashboards lists the dashboard names (without the .yaml
+// extension) of the files under testGridConfigDir that match the naming
+// scheme this tool generates.
+func existingManagedDashboards(testGridConfigDir string) (sets.String, error) {
+	matches, err := filepath.Glob(path.Join(testGridConfigDir, dashboardFilePrefix+"*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("could not glob for existing dashboard configs: %w", err)
+	}
+	names := sets.NewString()
+	for _, match := range matches {
+		names.Insert(strings.TrimSuffix(filepath.Base(match), ".yaml"))
+	}
+	return names, nil
+}
+
+// diffManagedDashboards compares the dashboards this run would generate
+// (named in generated, with their rendered YAML content) against what is
+// already on disk, and reports what --prune would add, remove and modify.
+func diffManagedDashboards(testGridConfigDir string, generated map[string][]byte) (pruneDiff, error) {
+	existing, err := existingManagedDashboards(testGridConfigDir)
+	if err != nil {
+		return pruneDiff{}, err
+	}
+	expected := sets.NewString()
+	for name := range generated {
+		expected.Insert(name)
+	}
+
+	var diff pruneDiff
+	diff.Added = expected.Difference(existing).List()
+	diff.Removed = existing.Difference(expected).List()
+	for _, name := range expected.Intersection(existing).List() {
+		onDisk, err := os.ReadFile(path.Join(testGridConfigDir, name+".yaml"))
+		if err != nil {
+			return pruneDiff{}, fmt.Errorf("could not read existing dashboard config %s: %w", name, err)
+		}
+		if !bytes.Equal(onDisk, generated[name]) {
+			diff.Modified = append(diff.Modified, name)
+		}
+	}
+	return diff, nil
+}
+
+// pruneStaleDashboards removes the on-disk YAML files for managed dashboards
+// that are no longer in the expected set, returning the names removed.
+func pruneStaleDashboards(testGridConfigDir string, expected sets.String) ([]string, error) {
+	existing, err := existingManagedDashboards(testGridConfigDir)
+	if err != nil {
+		return nil, err
+	}
+	stale := existing.Difference(expected).List()
+	for _, name := range stale {
+		if err := os.Remove(path.Join(testGridConfigDir, name+".yaml")); err != nil {
+			return nil, fmt.Errorf("could not remove stale dashboard config %s: %w", name, err)
+		}
+	}
+	return stale, nil
+}
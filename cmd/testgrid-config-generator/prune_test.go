@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestDiffManagedDashboards(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(path.Join(dir, name+".yaml"), []byte(content), 0600); err != nil {
+			t.Fatalf("could not write %s: %v", name, err)
+		}
+	}
+	write("redhat-openshift-ocp-release-4.8-blocking", "old")
+	write("redhat-openshift-ocp-release-4.9-blocking", "unchanged")
+	write("redhat-openshift-ocp-release-4.9-informing", "stale content")
+	write("some-other-dashboard", "not ours")
+
+	generated := map[string][]byte{
+		"redhat-openshift-ocp-release-4.9-blocking":  []byte("unchanged"),
+		"redhat-openshift-ocp-release-4.9-informing": []byte("fresh content"),
+		"redhat-openshift-ocp-release-4.10-blocking": []byte("new"),
+	}
+
+	diff, err := diffManagedDashboards(dir, generated)
+	if err != nil {
+		t.Fatalf("diffManagedDashboards() returned error: %v", err)
+	}
+	if want := []string{"redhat-openshift-ocp-release-4.10-blocking"}; !reflect.DeepEqual(diff.Added, want) {
+		t.Errorf("Added = %v, want %v", diff.Added, want)
+	}
+	if want := []string{"redhat-openshift-ocp-release-4.8-blocking"}; !reflect.DeepEqual(diff.Removed, want) {
+		t.Errorf("Removed = %v, want %v", diff.Removed, want)
+	}
+	if want := []string{"redhat-openshift-ocp-release-4.9-informing"}; !reflect.DeepEqual(diff.Modified, want) {
+		t.Errorf("Modified = %v, want %v", diff.Modified, want)
+	}
+	if diff.empty() {
+		t.Error("expected a non-empty diff")
+	}
+}
+
+func TestPruneStaleDashboards(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string) {
+		if err := os.WriteFile(path.Join(dir, name+".yaml"), []byte("content"), 0600); err != nil {
+			t.Fatalf("could not write %s: %v", name, err)
+		}
+	}
+	write("redhat-openshift-ocp-release-4.8-blocking")
+	write("redhat-openshift-ocp-release-4.9-blocking")
+	write("some-other-dashboard")
+
+	removed, err := pruneStaleDashboards(dir, sets.NewString("redhat-openshift-ocp-release-4.9-blocking"))
+	if err != nil {
+		t.Fatalf("pruneStaleDashboards() returned error: %v", err)
+	}
+	if want := []string{"redhat-openshift-ocp-release-4.8-blocking"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+	if _, err := os.Stat(path.Join(dir, "redhat-openshift-ocp-release-4.8-blocking.yaml")); !os.IsNotExist(err) {
+		t.Error("expected stale managed dashboard to be removed")
+	}
+	if _, err := os.Stat(path.Join(dir, "redhat-openshift-ocp-release-4.9-blocking.yaml")); err != nil {
+		t.Error("expected still-expected dashboard to remain")
+	}
+	if _, err := os.Stat(path.Join(dir, "some-other-dashboard.yaml")); err != nil {
+		t.Error("expected an unmanaged file to be left untouched")
+	}
+}
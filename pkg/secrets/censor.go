@@ -5,33 +5,170 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/fsnotify/fsnotify"
+
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/test-infra/prow/secretutil"
 )
 
+// manualSourceKey identifies the group of secrets added directly through
+// AddSecrets/RemoveSecrets on the root censor, as opposed to ones sourced
+// from a watched file/directory or a Scoped child.
+const manualSourceKey = ""
+
+// censorSources is the mutable state shared between a DynamicCensor and any
+// child censors returned by Scoped, so that adding or removing secrets
+// through one of them is immediately visible to the others.
+type censorSources struct {
+	sync.RWMutex
+	// byKey groups currently-active secret values by source: manualSourceKey
+	// for values added directly, a file/directory path for ones loaded by
+	// Watch/ReadFromDir, and "scope:<prefix>" for a Scoped child's values.
+	byKey map[string]sets.String
+}
+
 // DynamicCensor keeps a list of censored secrets that is dynamically updated.
 // Used when the list of secrets to censor is updated during the execution of
 // the program and cannot be determined in advance.  Access to the list of
 // secrets is internally synchronized.
 type DynamicCensor struct {
-	sync.RWMutex
 	*secretutil.ReloadingCensorer
-	secrets sets.String
+	sources *censorSources
+
+	// scope is empty for the root censor returned by NewDynamicCensor, or the
+	// prefix passed to Scoped for a child censor. It controls which key in
+	// sources.byKey AddSecrets, RemoveSecrets and Reset operate on.
+	scope string
+
+	// the following are only ever populated on a censor Watch was called on,
+	// and are guarded by watchMu rather than sources since they track this
+	// censor's own watch goroutine, not shared secret state.
+	watchMu      sync.Mutex
+	watcher      *fsnotify.Watcher
+	watchedFiles sets.String
+	watchedDirs  sets.String
+	stopCh       chan struct{}
+	wg           sync.WaitGroup
 }
 
-func NewDynamicCensor() DynamicCensor {
-	return DynamicCensor{
+// NewDynamicCensor returns a pointer, like Scoped, so that copying a
+// DynamicCensor after Watch has started its goroutine (e.g. by assigning it
+// to a non-pointer field, or passing it by value) can't silently detach
+// Close from the real watch goroutine's WaitGroup.
+func NewDynamicCensor() *DynamicCensor {
+	return &DynamicCensor{
 		ReloadingCensorer: secretutil.NewCensorer(),
-		secrets:           sets.NewString(),
+		sources:           &censorSources{byKey: map[string]sets.String{}},
+		watchedFiles:      sets.NewString(),
+		watchedDirs:       sets.NewString(),
 	}
 }
 
+// sourceKey returns the sources.byKey key that AddSecrets, RemoveSecrets and
+// Reset should operate on for this censor.
+func (c *DynamicCensor) sourceKey() string {
+	if c.scope == "" {
+		return manualSourceKey
+	}
+	return "scope:" + c.scope
+}
+
 // AddSecrets adds the content of one or more secrets to the censor list.
 func (c *DynamicCensor) AddSecrets(s ...string) {
-	c.Lock()
-	defer c.Unlock()
-	c.secrets.Insert(s...)
-	c.ReloadingCensorer.Refresh(c.secrets.List()...)
+	c.sources.Lock()
+	defer c.sources.Unlock()
+	key := c.sourceKey()
+	values := c.sources.byKey[key]
+	if values == nil {
+		values = sets.NewString()
+	}
+	values.Insert(s...)
+	c.sources.byKey[key] = values
+	c.refreshLocked()
+}
+
+// RemoveSecrets removes one or more secrets previously added through
+// AddSecrets (or, for a Scoped child, AddSecrets on that child) from the
+// censor list. It has no effect on secrets sourced from a watched file.
+func (c *DynamicCensor) RemoveSecrets(s ...string) {
+	c.sources.Lock()
+	defer c.sources.Unlock()
+	key := c.sourceKey()
+	values := c.sources.byKey[key]
+	if values.Len() == 0 {
+		return
+	}
+	values.Delete(s...)
+	if values.Len() == 0 {
+		delete(c.sources.byKey, key)
+	}
+	c.refreshLocked()
+}
+
+// Reset clears every secret this censor is responsible for: everything, for
+// the root censor returned by NewDynamicCensor, or just the secrets added
+// through this scope, for a child returned by Scoped.
+func (c *DynamicCensor) Reset() {
+	c.sources.Lock()
+	defer c.sources.Unlock()
+	if c.scope == "" {
+		c.sources.byKey = map[string]sets.String{}
+	} else {
+		delete(c.sources.byKey, c.sourceKey())
+	}
+	c.refreshLocked()
+}
+
+// Snapshot returns the current, sorted list of every secret being censored,
+// across every source, for tests and observability.
+func (c *DynamicCensor) Snapshot() []string {
+	c.sources.RLock()
+	defer c.sources.RUnlock()
+	all := sets.NewString()
+	for _, values := range c.sources.byKey {
+		all.Insert(values.List()...)
+	}
+	return all.List()
+}
+
+// Scoped returns a child censor that shares this DynamicCensor's reloader and
+// secret list, but tags every secret added through its AddSecrets with
+// prefix. Calling Reset on the child drops only the secrets it added,
+// leaving the rest of the censor list untouched. This is for long-lived
+// controllers that otherwise accumulate credentials from ephemeral jobs
+// forever: each job gets its own Scoped censor, dropped in one call once the
+// job is done.
+func (c *DynamicCensor) Scoped(prefix string) *DynamicCensor {
+	return &DynamicCensor{
+		ReloadingCensorer: c.ReloadingCensorer,
+		sources:           c.sources,
+		scope:             prefix,
+	}
+}
+
+// refreshLocked recomputes the full censor list from every currently known
+// source and pushes it to the underlying ReloadingCensorer. Callers must
+// hold sources' write lock.
+func (c *DynamicCensor) refreshLocked() {
+	all := sets.NewString()
+	for _, values := range c.sources.byKey {
+		all.Insert(values.List()...)
+	}
+	c.ReloadingCensorer.Refresh(all.List()...)
+}
+
+// updateSource replaces the set of values sourced from key (a watched file or
+// directory path) with values, removing any of its previous values that are
+// no longer present anywhere else and are therefore safe to stop censoring.
+func (c *DynamicCensor) updateSource(key string, values sets.String) {
+	c.sources.Lock()
+	defer c.sources.Unlock()
+	if values.Len() == 0 {
+		delete(c.sources.byKey, key)
+	} else {
+		c.sources.byKey[key] = values
+	}
+	c.refreshLocked()
 }
 
 // ReadFromEnv loads an environment variable and adds it to the censor list.
@@ -0,0 +1,67 @@
+package secrets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRemoveSecrets(t *testing.T) {
+	censor := NewDynamicCensor()
+	censor.AddSecrets("secret-a", "secret-b")
+	if !isCensored(t, censor, "secret-a") || !isCensored(t, censor, "secret-b") {
+		t.Fatal("expected both secrets to be censored after AddSecrets")
+	}
+
+	censor.RemoveSecrets("secret-a")
+	if isCensored(t, censor, "secret-a") {
+		t.Error("expected secret-a to stop being censored after RemoveSecrets")
+	}
+	if !isCensored(t, censor, "secret-b") {
+		t.Error("expected secret-b to remain censored")
+	}
+}
+
+func TestReset(t *testing.T) {
+	censor := NewDynamicCensor()
+	censor.AddSecrets("secret-a")
+	censor.Reset()
+	if isCensored(t, censor, "secret-a") {
+		t.Error("expected Reset to clear every secret")
+	}
+	if got := censor.Snapshot(); len(got) != 0 {
+		t.Errorf("expected empty snapshot after Reset, got %v", got)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	censor := NewDynamicCensor()
+	censor.AddSecrets("secret-b", "secret-a")
+	want := []string{"secret-a", "secret-b"}
+	if got := censor.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() = %v, want %v", got, want)
+	}
+}
+
+func TestScoped(t *testing.T) {
+	root := NewDynamicCensor()
+	root.AddSecrets("root-secret")
+
+	job := root.Scoped("job-123")
+	job.AddSecrets("job-secret")
+
+	if !isCensored(t, root, "root-secret") || !isCensored(t, root, "job-secret") {
+		t.Fatal("expected both the root's and the scoped child's secrets to be censored")
+	}
+	if !isCensored(t, job, "root-secret") {
+		t.Error("expected the scoped child to see secrets added on the root, since they share a censor list")
+	}
+
+	job.Reset()
+
+	if isCensored(t, root, "job-secret") {
+		t.Error("expected dropping the scope to stop censoring its secret")
+	}
+	if !isCensored(t, root, "root-secret") {
+		t.Error("expected dropping the scope to leave the root's own secrets alone")
+	}
+}
@@ -0,0 +1,198 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ReadFromDir treats every regular file in dir as an independent secret, the
+// layout used by a projected-volume Kubernetes Secret mount. Empty files are
+// ignored. The loaded values are registered under dir as their source,
+// replacing whatever was previously sourced from it.
+func ReadFromDir(dir string, censor *DynamicCensor) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("could not list secret directory %s: %w", dir, err)
+	}
+	values := sets.NewString()
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		// os.Stat follows symlinks, so the symlinked files of a Secret mount
+		// count as regular files
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// removed between ReadDir and Stat, e.g. during an atomic swap
+				continue
+			}
+			return fmt.Errorf("could not stat %s: %w", path, err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("could not read secret file %s: %w", path, err)
+		}
+		if trimmed := strings.TrimSpace(string(content)); trimmed != "" {
+			values.Insert(trimmed)
+		}
+	}
+	censor.updateSource(dir, values)
+	return nil
+}
+
+// readFileSource loads path as a single secret source, replacing whatever was
+// previously sourced from it. A missing file is treated as an empty source
+// rather than an error, since it may simply not have been created yet.
+func readFileSource(path string, censor *DynamicCensor) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			censor.updateSource(path, sets.NewString())
+			return nil
+		}
+		return fmt.Errorf("could not read secret file %s: %w", path, err)
+	}
+	values := sets.NewString()
+	if trimmed := strings.TrimSpace(string(content)); trimmed != "" {
+		values.Insert(trimmed)
+	}
+	censor.updateSource(path, values)
+	return nil
+}
+
+// Watch starts watching the given files and directories for changes and
+// keeps the censor list in sync with their contents. Files are read as a
+// single secret; directories are read with ReadFromDir. On any change the
+// affected source is re-read and, if a value disappeared from every source,
+// it stops being censored.
+//
+// The watch is robust to atomic replacement (e.g. the symlink swap a
+// Kubernetes Secret volume does on rotation) because it always watches the
+// parent directory rather than relying on a watch placed on a file surviving
+// past its inode being replaced.
+func (c *DynamicCensor) Watch(paths ...string) error {
+	c.watchMu.Lock()
+	if c.watcher == nil {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			c.watchMu.Unlock()
+			return fmt.Errorf("could not create secret file watcher: %w", err)
+		}
+		c.watcher = watcher
+		c.stopCh = make(chan struct{})
+		c.wg.Add(1)
+		go c.watchLoop(watcher, c.stopCh)
+	}
+	watcher := c.watcher
+	c.watchMu.Unlock()
+
+	for _, p := range paths {
+		// the source key must match the cleaned path handleEvent reloads under
+		p = filepath.Clean(p)
+		info, err := os.Stat(p)
+		if err != nil {
+			return fmt.Errorf("could not stat %s: %w", p, err)
+		}
+		if info.IsDir() {
+			if err := ReadFromDir(p, c); err != nil {
+				return err
+			}
+			if err := watcher.Add(p); err != nil {
+				return fmt.Errorf("could not watch directory %s: %w", p, err)
+			}
+			c.watchMu.Lock()
+			c.watchedDirs.Insert(filepath.Clean(p))
+			c.watchMu.Unlock()
+		} else {
+			if err := readFileSource(p, c); err != nil {
+				return err
+			}
+			if err := watcher.Add(filepath.Dir(p)); err != nil {
+				return fmt.Errorf("could not watch %s: %w", filepath.Dir(p), err)
+			}
+			c.watchMu.Lock()
+			c.watchedFiles.Insert(filepath.Clean(p))
+			c.watchMu.Unlock()
+		}
+	}
+	return nil
+}
+
+// Close stops the background watch goroutine started by Watch. It is safe to
+// call even if Watch was never called, and safe to call more than once.
+func (c *DynamicCensor) Close() error {
+	c.watchMu.Lock()
+	watcher := c.watcher
+	stopCh := c.stopCh
+	c.watcher = nil
+	c.watchMu.Unlock()
+
+	if watcher == nil {
+		return nil
+	}
+	close(stopCh)
+	err := watcher.Close()
+	c.wg.Wait()
+	return err
+}
+
+// watchLoop takes watcher and stopCh as parameters, rather than reading them
+// off of c, because Close clears c.watcher/c.stopCh under watchMu and this
+// loop must keep using the instances it started with without racing on them.
+func (c *DynamicCensor) watchLoop(watcher *fsnotify.Watcher, stopCh chan struct{}) {
+	defer c.wg.Done()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			c.handleEvent(event)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logrus.WithError(err).Warn("secret watcher received an error")
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+func (c *DynamicCensor) handleEvent(event fsnotify.Event) {
+	dir := filepath.Clean(filepath.Dir(event.Name))
+	name := filepath.Clean(event.Name)
+
+	c.watchMu.Lock()
+	watchedDir := c.watchedDirs.Has(dir)
+	var watchedFiles []string
+	for _, f := range c.watchedFiles.List() {
+		if f == name || filepath.Dir(f) == dir {
+			watchedFiles = append(watchedFiles, f)
+		}
+	}
+	c.watchMu.Unlock()
+
+	if watchedDir {
+		if err := ReadFromDir(dir, c); err != nil {
+			logrus.WithError(err).Warnf("could not reload secrets from %s", dir)
+		}
+	}
+	for _, f := range watchedFiles {
+		if err := readFileSource(f, c); err != nil {
+			logrus.WithError(err).Warnf("could not reload secret from %s", f)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package secrets
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func isCensored(t *testing.T, censor *DynamicCensor, value string) bool {
+	t.Helper()
+	content := []byte(value)
+	censor.Censor(&content)
+	return !bytes.Contains(content, []byte(value))
+}
+
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return condition()
+}
+
+func TestReadFromDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a"), []byte("secret-a\n"), 0600); err != nil {
+		t.Fatalf("could not write secret file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b"), []byte("secret-b"), 0600); err != nil {
+		t.Fatalf("could not write secret file: %v", err)
+	}
+
+	censor := NewDynamicCensor()
+	if err := ReadFromDir(dir, censor); err != nil {
+		t.Fatalf("ReadFromDir() returned error: %v", err)
+	}
+
+	if !isCensored(t, censor, "secret-a") {
+		t.Error("expected secret-a to be censored")
+	}
+	if !isCensored(t, censor, "secret-b") {
+		t.Error("expected secret-b to be censored")
+	}
+
+	if err := os.Remove(filepath.Join(dir, "b")); err != nil {
+		t.Fatalf("could not remove secret file: %v", err)
+	}
+	if err := ReadFromDir(dir, censor); err != nil {
+		t.Fatalf("ReadFromDir() returned error: %v", err)
+	}
+	if isCensored(t, censor, "secret-b") {
+		t.Error("expected secret-b to stop being censored once its file was removed")
+	}
+	if !isCensored(t, censor, "secret-a") {
+		t.Error("expected secret-a to remain censored")
+	}
+}
+
+// TestWatchAtomicRename simulates the symlink-swap rotation a Kubernetes
+// Secret volume performs: the new content is written to a side file and then
+// renamed over the watched file, rather than the watched file being
+// truncated and rewritten in place.
+func TestWatchAtomicRename(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "token")
+	if err := os.WriteFile(target, []byte("secret-v1"), 0600); err != nil {
+		t.Fatalf("could not write secret file: %v", err)
+	}
+
+	censor := NewDynamicCensor()
+	if err := censor.Watch(target); err != nil {
+		t.Fatalf("Watch() returned error: %v", err)
+	}
+	defer censor.Close()
+
+	if !isCensored(t, censor, "secret-v1") {
+		t.Fatal("expected secret-v1 to be censored immediately after Watch()")
+	}
+
+	staging := filepath.Join(dir, ".token.tmp")
+	if err := os.WriteFile(staging, []byte("secret-v2"), 0600); err != nil {
+		t.Fatalf("could not write staged secret file: %v", err)
+	}
+	if err := os.Rename(staging, target); err != nil {
+		t.Fatalf("could not atomically rotate secret file: %v", err)
+	}
+
+	if !waitFor(t, 2*time.Second, func() bool { return isCensored(t, censor, "secret-v2") }) {
+		t.Error("expected secret-v2 to become censored after rotation")
+	}
+	if !waitFor(t, 2*time.Second, func() bool { return !isCensored(t, censor, "secret-v1") }) {
+		t.Error("expected secret-v1 to stop being censored after rotation")
+	}
+}
+
+func TestWatchClose(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "token")
+	if err := os.WriteFile(target, []byte("secret-v1"), 0600); err != nil {
+		t.Fatalf("could not write secret file: %v", err)
+	}
+
+	censor := NewDynamicCensor()
+	if err := censor.Watch(target); err != nil {
+		t.Fatalf("Watch() returned error: %v", err)
+	}
+	if err := censor.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+	// Close should be idempotent: a second call after the watch has stopped is a no-op.
+	if err := censor.Close(); err != nil {
+		t.Fatalf("second Close() returned error: %v", err)
+	}
+
+	if err := os.WriteFile(target, []byte("secret-v2"), 0600); err != nil {
+		t.Fatalf("could not write secret file: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if isCensored(t, censor, "secret-v2") {
+		t.Error("expected no further updates to be picked up after Close()")
+	}
+}